@@ -0,0 +1,232 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GraphQLArtifact is the output of BuildGraphQLSchema: the schema text
+// itself plus a Go file of resolver stubs that delegate to the
+// existing typed client.
+type GraphQLArtifact struct {
+	Schema    string
+	Resolvers string
+}
+
+// graphQLOp pairs an endpoint with one of its (possibly several) HTTP
+// methods, since an endpoint declared with method=["GET","POST"] needs
+// to surface as both a Query and a Mutation field.
+type graphQLOp struct {
+	endpoint *EndpointInput
+	method   string
+}
+
+// BuildGraphQLSchema generates a schema.graphql and a gqlgen-style
+// resolver stub from the endpoints/types parseCode already extracted.
+// GET endpoints become Query fields, POST/PUT/PATCH/DELETE become
+// Mutation fields; struct TypeInputs become GraphQL object/input types.
+func BuildGraphQLSchema(services []ServiceInput) (*GraphQLArtifact, error) {
+	types := map[string]*TypeInput{}
+	requestTypes := map[string]bool{}
+	var queries, mutations []graphQLOp
+	for _, svc := range services {
+		for _, e := range svc.Endpoints {
+			for _, t := range e.Types {
+				types[t.Name] = t
+			}
+			if e.RequestType != "" {
+				requestTypes[e.RequestType] = true
+			}
+			for _, method := range e.Methods {
+				op := graphQLOp{endpoint: e, method: method}
+				if strings.ToUpper(method) == "GET" {
+					queries = append(queries, op)
+				} else {
+					mutations = append(mutations, op)
+				}
+			}
+		}
+	}
+
+	var schema strings.Builder
+	writeGraphQLTypes(&schema, types)
+	writeGraphQLInputTypes(&schema, types, requestTypes)
+	writeGraphQLOperations(&schema, "type Query", queries)
+	writeGraphQLOperations(&schema, "type Mutation", mutations)
+	fmt.Fprintln(&schema, "enum ErrorCode {")
+	for _, code := range sortedErrorCodes() {
+		fmt.Fprintf(&schema, "  %s\n", code)
+	}
+	fmt.Fprintln(&schema, "}")
+
+	var resolvers strings.Builder
+	fmt.Fprintln(&resolvers, "package graphql")
+	fmt.Fprintln(&resolvers)
+	fmt.Fprintln(&resolvers, "import \"context\"")
+	fmt.Fprintln(&resolvers)
+	fmt.Fprintln(&resolvers, "// Resolver delegates GraphQL operations to the underlying Encore")
+	fmt.Fprintln(&resolvers, "// endpoints via the existing typed client.")
+	fmt.Fprintln(&resolvers, "type Resolver struct{}")
+	fmt.Fprintln(&resolvers)
+	for _, op := range append(append([]graphQLOp{}, queries...), mutations...) {
+		e := op.endpoint
+		fmt.Fprintf(&resolvers, "func (r *Resolver) %s(ctx context.Context", exportedName(resolverName(e.Name, op.method, len(e.Methods))))
+		if e.RequestType != "" {
+			fmt.Fprintf(&resolvers, ", args %s", e.RequestType)
+		}
+		fmt.Fprintf(&resolvers, ") (%s, error) {\n", graphQLResolverReturnType(e))
+		fmt.Fprintln(&resolvers, "\tpanic(\"not implemented\")")
+		fmt.Fprintln(&resolvers, "}")
+		fmt.Fprintln(&resolvers)
+	}
+
+	return &GraphQLArtifact{
+		Schema:    schema.String(),
+		Resolvers: resolvers.String(),
+	}, nil
+}
+
+func graphQLResolverReturnType(e *EndpointInput) string {
+	if e.ResponseType == "" {
+		return "bool"
+	}
+	return "*" + e.ResponseType
+}
+
+func writeGraphQLTypes(w *strings.Builder, types map[string]*TypeInput) {
+	var names []string
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t := types[name]
+		fmt.Fprintf(w, "type %s {\n", graphQLTypeName(name))
+		for _, f := range t.Fields {
+			fmt.Fprintf(w, "  %s: %s\n", f.Name, goTypeToGraphQLType(f.Type))
+		}
+		fmt.Fprintln(w, "}")
+		fmt.Fprintln(w)
+	}
+}
+
+// writeGraphQLInputTypes emits an `input <Name>Input { ... }` block for
+// every type in requestTypes, recursing through struct-typed fields so
+// any nested request type also gets its own input declaration - GraphQL
+// input fields may only reference other input types, never the object
+// types writeGraphQLTypes emits.
+func writeGraphQLInputTypes(w *strings.Builder, types map[string]*TypeInput, requestTypes map[string]bool) {
+	emitted := map[string]bool{}
+	var emit func(name string)
+	emit = func(name string) {
+		if emitted[name] {
+			return
+		}
+		t, ok := types[name]
+		if !ok {
+			return
+		}
+		emitted[name] = true
+		fmt.Fprintf(w, "input %sInput {\n", graphQLTypeName(name))
+		for _, f := range t.Fields {
+			fmt.Fprintf(w, "  %s: %s\n", f.Name, goTypeToGraphQLInputType(f.Type, types, emit))
+		}
+		fmt.Fprintln(w, "}")
+		fmt.Fprintln(w)
+	}
+
+	var names []string
+	for name := range requestTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		emit(name)
+	}
+}
+
+// goTypeToGraphQLInputType is goTypeToGraphQLType's input-context
+// counterpart: a field referencing another known struct type emits
+// (and recurses into) that type's input declaration instead of its
+// object type.
+func goTypeToGraphQLInputType(typ string, types map[string]*TypeInput, emit func(string)) string {
+	if strings.HasPrefix(typ, "[]") {
+		return "[" + goTypeToGraphQLInputType(typ[2:], types, emit) + "]"
+	}
+	if _, ok := types[typ]; ok {
+		emit(typ)
+		return graphQLTypeName(typ) + "Input"
+	}
+	return goTypeToGraphQLType(typ)
+}
+
+func writeGraphQLOperations(w *strings.Builder, header string, ops []graphQLOp) {
+	fmt.Fprintln(w, header, "{")
+	for _, op := range ops {
+		e := op.endpoint
+		fmt.Fprintf(w, "  %s", resolverName(e.Name, op.method, len(e.Methods)))
+		if e.RequestType != "" {
+			fmt.Fprintf(w, "(input: %s)", graphQLTypeName(e.RequestType)+"Input")
+		}
+		fmt.Fprintf(w, ": %s\n", graphQLReturnType(e))
+	}
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+}
+
+// resolverName disambiguates field/resolver names for endpoints that
+// expose more than one HTTP method under the same handler name.
+func resolverName(name, method string, numMethods int) string {
+	if numMethods <= 1 {
+		return name
+	}
+	return name + "_" + strings.ToLower(method)
+}
+
+func graphQLReturnType(e *EndpointInput) string {
+	if e.ResponseType == "" {
+		return "Boolean"
+	}
+	return graphQLTypeName(e.ResponseType)
+}
+
+func graphQLTypeName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func goTypeToGraphQLType(typ string) string {
+	switch {
+	case strings.HasPrefix(typ, "[]"):
+		return "[" + goTypeToGraphQLType(typ[2:]) + "]"
+	case typ == "string":
+		return "String"
+	case strings.HasPrefix(typ, "int") || strings.HasPrefix(typ, "uint"):
+		return "Int"
+	case typ == "float32" || typ == "float64":
+		return "Float"
+	case typ == "bool":
+		return "Boolean"
+	default:
+		return graphQLTypeName(typ)
+	}
+}
+
+func sortedErrorCodes() []string {
+	codes := make([]string, 0, len(errIDToCode))
+	for code := range errIDToCode {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
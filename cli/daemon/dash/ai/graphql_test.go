@@ -0,0 +1,70 @@
+package ai
+
+import "testing"
+
+func TestBuildGraphQLSchemaEmitsInputTypes(t *testing.T) {
+	services := []ServiceInput{
+		{
+			Name: "widget",
+			Endpoints: []*EndpointInput{
+				{
+					Name:         "Create",
+					Methods:      []string{"POST"},
+					RequestType:  "widget.CreateParams",
+					ResponseType: "widget.Widget",
+					Types: []*TypeInput{
+						{
+							Name: "widget.CreateParams",
+							Fields: []*TypeFieldInput{
+								{Name: "Name", Type: "string"},
+								{Name: "Owner", Type: "widget.Owner"},
+							},
+						},
+						{
+							Name: "widget.Owner",
+							Fields: []*TypeFieldInput{
+								{Name: "ID", Type: "string"},
+							},
+						},
+						{
+							Name: "widget.Widget",
+							Fields: []*TypeFieldInput{
+								{Name: "Name", Type: "string"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	artifact, err := BuildGraphQLSchema(services)
+	if err != nil {
+		t.Fatalf("BuildGraphQLSchema: %v", err)
+	}
+
+	if !containsLine(artifact.Schema, "input CreateParamsInput {") {
+		t.Errorf("expected an input type for the request type, got schema:\n%s", artifact.Schema)
+	}
+	if !containsLine(artifact.Schema, "input OwnerInput {") {
+		t.Errorf("expected the nested struct field to recurse into its own input type, got schema:\n%s", artifact.Schema)
+	}
+	if !containsLine(artifact.Schema, "Owner: OwnerInput") {
+		t.Errorf("expected the Owner field to reference OwnerInput, not the object type, got schema:\n%s", artifact.Schema)
+	}
+	if containsLine(artifact.Schema, "type Owner {") == false {
+		t.Errorf("expected the object type for Owner to still be emitted for responses, got schema:\n%s", artifact.Schema)
+	}
+	if !containsLine(artifact.Schema, "Create(input: CreateParamsInput): Widget") {
+		t.Errorf("expected the Mutation field to reference the input type, got schema:\n%s", artifact.Schema)
+	}
+}
+
+func containsLine(s, substr string) bool {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
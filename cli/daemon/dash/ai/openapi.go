@@ -0,0 +1,335 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// openAPIDocument is a minimal representation of the subset of the
+// OpenAPI 3.1 object model that ExportOpenAPI needs to populate.
+type openAPIDocument struct {
+	OpenAPI    string                      `json:"openapi"`
+	Info       openAPIInfo                 `json:"info"`
+	Paths      map[string]*openAPIPathItem `json:"paths"`
+	Components openAPIComponents           `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `json:"schemas"`
+}
+
+type openAPIPathItem struct {
+	Get    *openAPIOperation `json:"get,omitempty"`
+	Post   *openAPIOperation `json:"post,omitempty"`
+	Put    *openAPIOperation `json:"put,omitempty"`
+	Patch  *openAPIOperation `json:"patch,omitempty"`
+	Delete *openAPIOperation `json:"delete,omitempty"`
+}
+
+func (p *openAPIPathItem) set(method string, op *openAPIOperation) {
+	switch strings.ToUpper(method) {
+	case "GET":
+		p.Get = op
+	case "POST":
+		p.Post = op
+	case "PUT":
+		p.Put = op
+	case "PATCH":
+		p.Patch = op
+	case "DELETE":
+		p.Delete = op
+	}
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary,omitempty"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Content map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Headers     map[string]*openAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIHeader struct {
+	Description string         `json:"description,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref         string                    `json:"$ref,omitempty"`
+	Type        string                    `json:"type,omitempty"`
+	Properties  map[string]*openAPISchema `json:"properties,omitempty"`
+	Items       *openAPISchema            `json:"items,omitempty"`
+	Required    []string                  `json:"required,omitempty"`
+	Description string                    `json:"description,omitempty"`
+}
+
+// ExportOpenAPI turns the endpoints discovered for services into an
+// OpenAPI 3.1 document. It's driven entirely off the schema parseCode
+// already extracted (EndpointInput/TypeInput/ErrorInput), rather than
+// off source comment annotations.
+func ExportOpenAPI(services []ServiceInput) ([]byte, error) {
+	doc := &openAPIDocument{
+		OpenAPI: "3.1.0",
+		Info: openAPIInfo{
+			Title:   "Encore API",
+			Version: "1.0.0",
+		},
+		Paths: map[string]*openAPIPathItem{},
+		Components: openAPIComponents{
+			Schemas: map[string]*openAPISchema{},
+		},
+	}
+
+	for _, svc := range services {
+		for _, e := range svc.Endpoints {
+			for _, t := range e.Types {
+				addSchema(doc, t)
+			}
+			reqParams := requestParameters(e)
+
+			for _, method := range e.Methods {
+				pathStr, pathParams := openAPIPath(e.Path, e.MethodPathDocs[method])
+				item, ok := doc.Paths[pathStr]
+				if !ok {
+					item = &openAPIPathItem{}
+					doc.Paths[pathStr] = item
+				}
+
+				op := &openAPIOperation{
+					OperationID: operationID(e.Name, method, len(e.Methods)),
+					Summary:     e.Doc,
+					Parameters:  append(append([]*openAPIParameter{}, pathParams...), reqParams...),
+					Responses:   map[string]*openAPIResponse{},
+				}
+
+				if e.RequestType != "" {
+					op.RequestBody = &openAPIRequestBody{
+						Content: map[string]*openAPIMediaType{
+							"application/json": {Schema: schemaRef(e.RequestType)},
+						},
+					}
+				}
+
+				if e.ResponseType != "" {
+					op.Responses["200"] = &openAPIResponse{
+						Description: "OK",
+						Headers:     responseHeaders(e),
+						Content: map[string]*openAPIMediaType{
+							"application/json": {Schema: schemaRef(e.ResponseType)},
+						},
+					}
+				} else {
+					op.Responses["200"] = &openAPIResponse{Description: "OK"}
+				}
+
+				for _, errIn := range e.Errors {
+					if errIn.Method != "" && errIn.Method != method {
+						continue
+					}
+					code := errIDToCode[errIn.Code]
+					if code == 0 {
+						code = 500
+					}
+					op.Responses[fmt.Sprintf("%d", code)] = &openAPIResponse{
+						Description: errIn.Doc,
+					}
+				}
+
+				item.set(method, op)
+			}
+		}
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// openAPIPath converts a parsed endpoint path into an OpenAPI path
+// template (":id" style params become "{id}") and a matching set of
+// "in: path" parameters built from their doc comments. methodDocs, if
+// non-nil, overrides a param's doc for the method being emitted.
+func openAPIPath(segs []*PathSegment, methodDocs map[string]string) (string, []*openAPIParameter) {
+	var b strings.Builder
+	var params []*openAPIParameter
+	for _, seg := range segs {
+		b.WriteString("/")
+		switch seg.Type {
+		case PathSegmentParam, PathSegmentWildcard:
+			b.WriteString("{" + seg.Value + "}")
+			doc := seg.Doc
+			if d, ok := methodDocs[seg.Value]; ok {
+				doc = d
+			}
+			params = append(params, &openAPIParameter{
+				Name:        seg.Value,
+				In:          "path",
+				Description: doc,
+				Required:    true,
+				Schema:      &openAPISchema{Type: "string"},
+			})
+		default:
+			b.WriteString(seg.Value)
+		}
+	}
+	if b.Len() == 0 {
+		return "/", params
+	}
+	return b.String(), params
+}
+
+// operationID disambiguates operation IDs for endpoints that expose
+// more than one HTTP method under the same handler name.
+func operationID(name, method string, numMethods int) string {
+	if numMethods <= 1 {
+		return name
+	}
+	return name + "_" + strings.ToLower(method)
+}
+
+func schemaRef(name string) *openAPISchema {
+	return &openAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+// addSchema registers t's components.schemas entry, skipping fields
+// whose Location puts them in the header or query string rather than
+// the body — those are surfaced on the operation via
+// requestParameters instead.
+func addSchema(doc *openAPIDocument, t *TypeInput) {
+	if _, ok := doc.Components.Schemas[t.Name]; ok {
+		return
+	}
+	s := &openAPISchema{
+		Type:        "object",
+		Description: t.Doc,
+		Properties:  map[string]*openAPISchema{},
+	}
+	for _, f := range t.Fields {
+		if _, ok := paramLocationToOpenAPIIn(f.Location); ok {
+			continue
+		}
+		fieldSchema := goTypeToOpenAPISchema(f.Type)
+		fieldSchema.Description = f.Doc
+		name := f.WireName
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = fieldSchema
+	}
+	doc.Components.Schemas[t.Name] = s
+}
+
+// requestParameters builds the header/query openAPIParameters for e's
+// request type. Body-located fields are left to the requestBody
+// schema built by addSchema.
+func requestParameters(e *EndpointInput) []*openAPIParameter {
+	var params []*openAPIParameter
+	for _, t := range e.Types {
+		if t.Name != e.RequestType {
+			continue
+		}
+		for _, f := range t.Fields {
+			in, ok := paramLocationToOpenAPIIn(f.Location)
+			if !ok {
+				continue
+			}
+			name := f.WireName
+			if name == "" {
+				name = f.Name
+			}
+			params = append(params, &openAPIParameter{
+				Name:        name,
+				In:          in,
+				Description: f.Doc,
+				Schema:      goTypeToOpenAPISchema(f.Type),
+			})
+		}
+	}
+	return params
+}
+
+// responseHeaders builds the openAPIHeaders for e's response type's
+// header-located fields. Body-located fields are left to the response
+// content schema built by addSchema; query-located fields can't occur on
+// a response and are ignored.
+func responseHeaders(e *EndpointInput) map[string]*openAPIHeader {
+	var headers map[string]*openAPIHeader
+	for _, t := range e.Types {
+		if t.Name != e.ResponseType {
+			continue
+		}
+		for _, f := range t.Fields {
+			if f.Location != "header" {
+				continue
+			}
+			name := f.WireName
+			if name == "" {
+				name = f.Name
+			}
+			if headers == nil {
+				headers = map[string]*openAPIHeader{}
+			}
+			headers[name] = &openAPIHeader{
+				Description: f.Doc,
+				Schema:      goTypeToOpenAPISchema(f.Type),
+			}
+		}
+	}
+	return headers
+}
+
+// paramLocationToOpenAPIIn maps an apienc.ParameterEncoding location to
+// the OpenAPI "in" value for non-body parameters.
+func paramLocationToOpenAPIIn(location string) (string, bool) {
+	switch location {
+	case "header":
+		return "header", true
+	case "query":
+		return "query", true
+	default:
+		return "", false
+	}
+}
+
+func goTypeToOpenAPISchema(typ string) *openAPISchema {
+	switch {
+	case strings.HasPrefix(typ, "[]"):
+		return &openAPISchema{Type: "array", Items: goTypeToOpenAPISchema(typ[2:])}
+	case typ == "string":
+		return &openAPISchema{Type: "string"}
+	case strings.HasPrefix(typ, "int") || strings.HasPrefix(typ, "uint"):
+		return &openAPISchema{Type: "integer"}
+	case typ == "float32" || typ == "float64":
+		return &openAPISchema{Type: "number"}
+	case typ == "bool":
+		return &openAPISchema{Type: "boolean"}
+	default:
+		return schemaRef(typ)
+	}
+}
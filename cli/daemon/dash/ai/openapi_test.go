@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExportOpenAPIRequestAndResponseParameters(t *testing.T) {
+	services := []ServiceInput{
+		{
+			Name: "widget",
+			Endpoints: []*EndpointInput{
+				{
+					Name:         "Get",
+					Doc:          "Get fetches a widget.",
+					Methods:      []string{"GET"},
+					Path:         []*PathSegment{{Type: PathSegmentParam, Value: "id", Doc: "the widget's ID"}},
+					RequestType:  "widget.GetParams",
+					ResponseType: "widget.GetResponse",
+					Types: []*TypeInput{
+						{
+							Name: "widget.GetParams",
+							Fields: []*TypeFieldInput{
+								{Name: "Auth", WireName: "Authorization", Location: "header", Type: "string"},
+							},
+						},
+						{
+							Name: "widget.GetResponse",
+							Fields: []*TypeFieldInput{
+								{Name: "Name", WireName: "name", Type: "string"},
+								{Name: "RateLimit", WireName: "X-RateLimit-Remaining", Location: "header", Type: "int"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	out, err := ExportOpenAPI(services)
+	if err != nil {
+		t.Fatalf("ExportOpenAPI: %v", err)
+	}
+
+	var doc openAPIDocument
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("unmarshal generated document: %v", err)
+	}
+
+	item, ok := doc.Paths["/{id}"]
+	if !ok || item.Get == nil {
+		t.Fatalf("expected a GET operation at /{id}, got paths %+v", doc.Paths)
+	}
+
+	var sawAuthHeader bool
+	for _, p := range item.Get.Parameters {
+		if p.Name == "Authorization" && p.In == "header" {
+			sawAuthHeader = true
+		}
+	}
+	if !sawAuthHeader {
+		t.Errorf("expected an Authorization header parameter, got %+v", item.Get.Parameters)
+	}
+
+	bodySchema := doc.Components.Schemas["widget.GetParams"]
+	if bodySchema == nil {
+		t.Fatalf("missing widget.GetParams schema")
+	}
+	if _, ok := bodySchema.Properties["Authorization"]; ok {
+		t.Errorf("header-located field leaked into the request body schema: %+v", bodySchema.Properties)
+	}
+
+	resp := item.Get.Responses["200"]
+	if resp == nil {
+		t.Fatalf("missing 200 response")
+	}
+	header, ok := resp.Headers["X-RateLimit-Remaining"]
+	if !ok {
+		t.Fatalf("expected an X-RateLimit-Remaining response header, got %+v", resp.Headers)
+	}
+	if header.Schema == nil || header.Schema.Type != "integer" {
+		t.Errorf("response header schema = %+v, want integer", header.Schema)
+	}
+
+	respSchema := doc.Components.Schemas["widget.GetResponse"]
+	if respSchema == nil {
+		t.Fatalf("missing widget.GetResponse schema")
+	}
+	if _, ok := respSchema.Properties["X-RateLimit-Remaining"]; ok {
+		t.Errorf("header-located field leaked into the response body schema: %+v", respSchema.Properties)
+	}
+	if _, ok := respSchema.Properties["name"]; !ok {
+		t.Errorf("expected body-located field name in response schema, got %+v", respSchema.Properties)
+	}
+}
@@ -2,10 +2,13 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"go/token"
+	"regexp"
 	"runtime"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 
@@ -43,73 +46,124 @@ var errIDToCode = map[string]int{
 	"Unauthenticated":    401,
 }
 
+// docSectionHeader matches a doc section header, with an optional
+// HTTP-method qualifier: "Errors:" or "Errors[POST]:".
+var docSectionHeader = regexp.MustCompile(`^([A-Za-z]+)(?:\[([A-Za-z]+)\])?:$`)
+
 type DocEntry struct {
 	Key string
 	Doc string
+	// Method is the HTTP method a section was qualified with, e.g.
+	// "POST" in "Errors[POST]:". Empty if the section applies to
+	// every method the endpoint is declared with.
+	Method string
 }
 
 func parseErrorDoc(doc string) (string, []*ErrorInput) {
-	doc, errs := parseDocSection(doc, ErrDocPrefix)
-	return doc, fns.Map(errs, func(e DocEntry) *ErrorInput {
+	doc, entries := parseDocSection(doc, ErrDocPrefix)
+	return doc, fns.Map(entries, func(e DocEntry) *ErrorInput {
 		return &ErrorInput{
-			Code: e.Key,
-			Doc:  e.Doc,
+			Code:   e.Key,
+			Doc:    e.Doc,
+			Method: e.Method,
 		}
 	})
 }
 
+// parsePathDoc returns the path-param docs found in doc, keyed by
+// pathDocKey so a method-qualified entry ("Path[GET]:") doesn't
+// clobber an unscoped one ("Path:") for the same param.
 func parsePathDoc(doc string) (string, map[string]string) {
-	doc, docs := parseDocSection(doc, PathDocPrefix)
+	doc, entries := parseDocSection(doc, PathDocPrefix)
 	rtn := map[string]string{}
-	for _, d := range docs {
-		rtn[d.Key] = d.Doc
+	for _, d := range entries {
+		rtn[pathDocKey(d.Method, d.Key)] = d.Doc
 	}
 	return doc, rtn
 }
 
+// pathDocKey builds the lookup key used in parsePathDoc's result:
+// unscoped entries are keyed by param name alone, method-scoped ones
+// by "METHOD param".
+func pathDocKey(method, param string) string {
+	if method == "" {
+		return param
+	}
+	return method + " " + param
+}
+
+// unscopedPathDocs strips the method qualifier back out of a
+// parsePathDoc result, keeping only the entries that apply to every
+// method. It's what toPathSegments is given, preserving the single
+// unqualified Path section behavior unscoped endpoints have always had.
+func unscopedPathDocs(pathDocs map[string]string) map[string]string {
+	rtn := map[string]string{}
+	for key, doc := range pathDocs {
+		if !strings.Contains(key, " ") {
+			rtn[key] = doc
+		}
+	}
+	return rtn
+}
+
+// methodPathDocs resolves, for each of an endpoint's methods, the
+// path-param docs that apply to it: a method-scoped entry
+// ("Path[POST]:") takes precedence over the unscoped one for that
+// param. Used by the OpenAPI/GraphQL emitters to fan out per-method
+// path-parameter descriptions.
+func methodPathDocs(pathDocs map[string]string, methods []string) map[string]map[string]string {
+	rtn := map[string]map[string]string{}
+	for _, method := range methods {
+		merged := map[string]string{}
+		for key, doc := range pathDocs {
+			if m, param, ok := strings.Cut(key, " "); !ok {
+				merged[key] = doc
+			} else if m == method {
+				merged[param] = doc
+			}
+		}
+		rtn[method] = merged
+	}
+	return rtn
+}
+
+// parseDocSection extracts every occurrence of a `section:` or
+// `section[METHOD]:` block from doc, returning doc with those blocks
+// stripped out and the entries they contained. A doc can repeat the
+// same section once per method (e.g. "Errors:" followed later by
+// "Errors[POST]:") to document per-verb differences on a multi-method
+// endpoint.
 func parseDocSection(doc, section string) (string, []DocEntry) {
-	var errs []DocEntry
+	var entries []DocEntry
 	lines := strings.Split(doc, "\n")
-	start := -1
-	end := -1
-	for i, line := range lines {
-		end = i
-		if strings.HasPrefix(strings.TrimSpace(line), section+":") {
-			start = i
+	var kept []string
 
-		} else if start == -1 {
+	i := 0
+	for i < len(lines) {
+		m := docSectionHeader.FindStringSubmatch(strings.TrimSpace(lines[i]))
+		if m == nil || m[1] != section {
+			kept = append(kept, lines[i])
+			i++
 			continue
-		} else if len(line) > 2 {
-			switch strings.TrimSpace(line[:2]) {
-			case "-", "":
-			default:
-				end = i - 1
+		}
+		method := m[2]
+		i++
+		for i < len(lines) {
+			trimmed := strings.TrimSpace(lines[i])
+			if trimmed == "" || docSectionHeader.MatchString(trimmed) {
 				break
 			}
-		}
-		lines[i] = strings.TrimSpace(line)
-		if line == "" && lines[i-1] == "" {
-			break
-		}
-	}
-	if start == -1 {
-		return doc, errs
-	}
-
-	for _, line := range lines[start+1 : end+1] {
-		key, doc, ok := strings.Cut(line, ":")
-		key = strings.TrimPrefix(key, "-")
-		key = strings.TrimSpace(key)
-		if ok {
-			errs = append(errs, DocEntry{
-				Key: key,
-				Doc: strings.TrimSpace(doc),
-			})
-		} else if len(errs) > 0 && line != "" {
-			errs[len(errs)-1].Doc += "\n" + line
+			key, d, ok := strings.Cut(trimmed, ":")
+			key = strings.TrimSpace(strings.TrimPrefix(key, "-"))
+			if ok {
+				entries = append(entries, DocEntry{Key: key, Doc: strings.TrimSpace(d), Method: method})
+			} else if len(entries) > 0 {
+				entries[len(entries)-1].Doc += "\n" + trimmed
+			}
+			i++
 		}
 	}
-	return strings.Join(lines[:start], "\n"), errs
+	return strings.TrimSpace(strings.Join(kept, "\n")), entries
 }
 
 func deref(p schema.Type) schema.Type {
@@ -122,7 +176,113 @@ func deref(p schema.Type) schema.Type {
 	}
 }
 
-func parseCode(ctx context.Context, app *apps.Instance, services []ServiceInput) (rtn *SyncResult, err error) {
+// applyEndpoint fills in the overlay endpoint matching r's source file
+// with everything parsed off its resource declaration. It's shared
+// between parseCode's full rebuild and Watcher's incremental reparse.
+func applyEndpoint(overlays *overlays, r *api.Endpoint) {
+	overlay, ok := overlays.get(r.File.FSPath)
+	if !ok {
+		return
+	}
+	e := overlay.endpoint
+	pathDocs := map[string]string{}
+	e.Doc, e.Errors = parseErrorDoc(r.Doc)
+	e.Doc, pathDocs = parsePathDoc(e.Doc)
+	e.Name = r.Name
+	e.Methods = r.HTTPMethods
+	e.Method = e.Methods[0] // Deprecated: use Methods instead.
+	e.Visibility = VisibilityType(r.Access)
+	e.Language = "GO"
+	e.Path = toPathSegments(r.Path, unscopedPathDocs(pathDocs))
+	e.MethodPathDocs = methodPathDocs(pathDocs, e.Methods)
+	e.Types = []*TypeInput{}
+	if nr, ok := deref(r.Request).(schema.NamedType); ok {
+		e.RequestType = nr.String()
+		if len(r.RequestEncoding()) > 0 {
+			e.Types = append(e.Types, &TypeInput{
+				Name: nr.String(),
+				Doc:  strings.TrimSpace(nr.DeclInfo.Doc),
+				Fields: fns.Map(r.RequestEncoding()[0].AllParameters(), func(f *apienc.ParameterEncoding) *TypeFieldInput {
+					return &TypeFieldInput{
+						Name:     f.SrcName,
+						WireName: f.WireName,
+						Location: f.Location,
+						Type:     f.Type.String(),
+						Doc:      strings.TrimSpace(f.Doc),
+					}
+				}),
+			})
+		}
+	}
+	if nr, ok := deref(r.Response).(schema.NamedType); ok {
+		e.ResponseType = nr.String()
+		if r.ResponseEncoding() != nil {
+			e.Types = append(e.Types, &TypeInput{
+				Name: nr.String(),
+				Doc:  strings.TrimSpace(nr.DeclInfo.Doc),
+				Fields: fns.Map(r.ResponseEncoding().AllParameters(), func(f *apienc.ParameterEncoding) *TypeFieldInput {
+					return &TypeFieldInput{
+						Name:     f.SrcName,
+						WireName: f.WireName,
+						Location: f.Location,
+						Type:     f.Type.String(),
+						Doc:      strings.TrimSpace(f.Doc),
+					}
+				}),
+			})
+		}
+	}
+}
+
+// applyTypeDecl appends d to the overlay endpoint's Types if it's a
+// struct declared in a watched overlay file and isn't already present.
+func applyTypeDecl(overlays *overlays, d *schema.TypeDecl) {
+	schemaType, ok := d.Type.(schema.StructType)
+	if !ok {
+		return
+	}
+	overlay, ok := overlays.get(d.File.FSPath)
+	if !ok {
+		return
+	}
+	e := overlay.endpoint
+	if slices.ContainsFunc(e.Types, func(t *TypeInput) bool { return t.Name == d.Name }) {
+		return
+	}
+	e.Types = append(e.Types, &TypeInput{
+		Name: d.Name,
+		Doc:  strings.TrimSpace(d.Info.Doc),
+		Fields: fns.Map(schemaType.Fields, func(f schema.StructField) *TypeFieldInput {
+			return &TypeFieldInput{
+				Name: f.Name.String(),
+				Type: f.Type.String(),
+				Doc:  strings.TrimSpace(f.Doc),
+			}
+		}),
+	})
+}
+
+// ParseOptions bounds how long parseCode is allowed to run. Both
+// fields are optional; a zero value imposes no limit beyond ctx's own
+// cancellation.
+type ParseOptions struct {
+	// Deadline bounds the overall parse. Once reached, parseCode
+	// returns whatever SyncResult has been filled in so far, along
+	// with ctx.Err().
+	Deadline time.Time
+	// PackageTimeout bounds how long a single package's resource and
+	// schema parsing may take before that package is abandoned in
+	// favor of moving on to the next one.
+	PackageTimeout time.Duration
+}
+
+func parseCode(ctx context.Context, app *apps.Instance, services []ServiceInput, opts ParseOptions) (rtn *SyncResult, err error) {
+	if !opts.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, opts.Deadline)
+		defer cancel()
+	}
+
 	overlays, err := newOverlays(app, false, services...)
 	if err != nil {
 		return nil, err
@@ -145,116 +305,121 @@ func parseCode(ctx context.Context, app *apps.Instance, services []ServiceInput)
 		Errs:          errs,
 		Overlay:       overlays,
 	}
+	// activeCtx tracks the context the package currently being parsed is
+	// running under, so the deferred recover below can tell a
+	// PackageTimeout expiring apart from ctx itself being done - both
+	// unwind through parsePackage as a panic, but only ctx.Err() means
+	// the whole parse was cancelled. firstPkgTimeout is the separate,
+	// sticky record of whether *any* earlier package timed out and
+	// returned normally (no panic) - by the time parseCode returns,
+	// activeCtx only reflects whichever package ran last, so it alone
+	// would miss a timeout on an earlier one.
+	activeCtx := ctx
+	var firstPkgTimeout error
 	defer func() {
-		perr.CatchBailout(recover())
+		// activeCtx's timeout context is also cancel()'d on every
+		// normal per-package completion further down, which makes its
+		// Err() report context.Canceled even though nothing actually
+		// timed out; only context.DeadlineExceeded means this package
+		// was genuinely abandoned mid-parse.
+		pkgTimedOut := errors.Is(activeCtx.Err(), context.DeadlineExceeded)
+
+		// A panic while ctx is already done, or the active package's
+		// own timeout fired, means a blocking step noticed cancellation
+		// and unwound, not that the parser hit a genuine bug; only
+		// route the latter through CatchBailout.
+		if r := recover(); r != nil && ctx.Err() == nil && !pkgTimedOut {
+			perr.CatchBailout(r)
+		}
 		if rtn == nil {
 			rtn = &SyncResult{
 				Services: services,
 			}
 		}
 		rtn.Errors = overlays.validationErrors(errs)
+		switch {
+		case ctx.Err() != nil:
+			err = ctx.Err()
+		case pkgTimedOut:
+			err = activeCtx.Err()
+		case firstPkgTimeout != nil:
+			err = firstPkgTimeout
+		}
 	}()
 
 	loader := pkginfo.New(pc)
 
 	pkgs := map[paths.Pkg]*pkginfo.Package{}
 	for _, pkg := range overlays.pkgPaths() {
+		if ctx.Err() != nil {
+			return &SyncResult{Services: services}, ctx.Err()
+		}
 		pkgs[pkg], _ = loader.LoadPkg(token.NoPos, pkg)
 	}
 	schemaParser := schema.NewParser(pc, loader)
 	for _, pkg := range pkgs {
-		pass := &resourceparser.Pass{
-			Context:      pc,
-			SchemaParser: schemaParser,
-			Pkg:          pkg,
-		}
-		apis.Parser.Run(pass)
-		for _, r := range pass.Resources() {
-			switch r := r.(type) {
-			case *api.Endpoint:
-				overlay, ok := overlays.get(r.File.FSPath)
-				if !ok {
-					continue
-				}
-				e := overlay.endpoint
-				pathDocs := map[string]string{}
-				e.Doc, e.Errors = parseErrorDoc(r.Doc)
-				e.Doc, pathDocs = parsePathDoc(e.Doc)
-				e.Name = r.Name
-				e.Method = r.HTTPMethods[0]
-				e.Visibility = VisibilityType(r.Access)
-				e.Language = "GO"
-				e.Path = toPathSegments(r.Path, pathDocs)
-				e.Types = []*TypeInput{}
-				if nr, ok := deref(r.Request).(schema.NamedType); ok {
-					e.RequestType = nr.String()
-					if len(r.RequestEncoding()) > 0 {
-						e.Types = append(e.Types, &TypeInput{
-							Name: nr.String(),
-							Doc:  strings.TrimSpace(nr.DeclInfo.Doc),
-							Fields: fns.Map(r.RequestEncoding()[0].AllParameters(), func(f *apienc.ParameterEncoding) *TypeFieldInput {
-								return &TypeFieldInput{
-									Name:     f.SrcName,
-									WireName: f.WireName,
-									Location: f.Location,
-									Type:     f.Type.String(),
-									Doc:      strings.TrimSpace(f.Doc),
-								}
-							}),
-						})
-					}
-				}
-				if nr, ok := deref(r.Response).(schema.NamedType); ok {
-					e.ResponseType = nr.String()
-					if r.ResponseEncoding() != nil {
-						e.Types = append(e.Types, &TypeInput{
-							Name: nr.String(),
-							Doc:  strings.TrimSpace(nr.DeclInfo.Doc),
-							Fields: fns.Map(r.ResponseEncoding().AllParameters(), func(f *apienc.ParameterEncoding) *TypeFieldInput {
-								return &TypeFieldInput{
-									Name:     f.SrcName,
-									WireName: f.WireName,
-									Location: f.Location,
-									Type:     f.Type.String(),
-									Doc:      strings.TrimSpace(f.Doc),
-								}
-							}),
-						})
-					}
-				}
-			}
-
+		if ctx.Err() != nil {
+			return &SyncResult{Services: services}, ctx.Err()
 		}
-		for _, d := range schemaParser.ParsedDecls() {
-			switch d := d.(type) {
-			case *schema.TypeDecl:
-				schemaType, ok := d.Type.(schema.StructType)
-				if !ok {
-					continue
-				}
-				overlay, ok := overlays.get(d.File.FSPath)
-				if !ok {
-					continue
-				}
-				e := overlay.endpoint
-				if slices.ContainsFunc(e.Types, func(t *TypeInput) bool { return t.Name == d.Name }) {
-					continue
-				}
-				e.Types = append(e.Types, &TypeInput{
-					Name: d.Name,
-					Doc:  strings.TrimSpace(d.Info.Doc),
-					Fields: fns.Map(schemaType.Fields, func(f schema.StructField) *TypeFieldInput {
-						return &TypeFieldInput{
-							Name: f.Name.String(),
-							Type: f.Type.String(),
-							Doc:  strings.TrimSpace(f.Doc),
-						}
-					}),
-				})
+		func() {
+			pkgCtx, cancel := packageContext(ctx, opts.PackageTimeout)
+			defer cancel()
+			activeCtx = pkgCtx
+			parsePackage(pkgCtx, overlays, schemaParser, pc, pkg)
+			if firstPkgTimeout == nil && errors.Is(pkgCtx.Err(), context.DeadlineExceeded) {
+				firstPkgTimeout = pkgCtx.Err()
 			}
-		}
+		}()
 	}
 	return &SyncResult{
 		Services: services,
 	}, nil
-}
\ No newline at end of file
+}
+
+// packageContext derives the context a single package's parse should run
+// under: ctx itself if there's no PackageTimeout, or a timeout-bound
+// child of it otherwise.
+func packageContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// parsePackage runs the resource and schema parsers over a single
+// package and folds the results into overlays. It checks ctx between
+// every resource and every decl so a cancellation or per-package timeout
+// stops work promptly instead of running the whole package to
+// completion.
+func parsePackage(ctx context.Context, overlays *overlays, schemaParser *schema.Parser, pc *parsectx.Context, pkg *pkginfo.Package) {
+	// apis.Parser.Run below only observes cancellation through pc.Ctx,
+	// not the ctx parameter directly, so ctx has to flow into a derived
+	// parsectx.Context - otherwise a hung parse would run unbounded
+	// despite the ctx.Err() checks in the loops further down.
+	withCtx := *pc
+	withCtx.Ctx = ctx
+	pc = &withCtx
+
+	pass := &resourceparser.Pass{
+		Context:      pc,
+		SchemaParser: schemaParser,
+		Pkg:          pkg,
+	}
+	apis.Parser.Run(pass)
+	for _, r := range pass.Resources() {
+		if ctx.Err() != nil {
+			return
+		}
+		if e, ok := r.(*api.Endpoint); ok {
+			applyEndpoint(overlays, e)
+		}
+	}
+	for _, d := range schemaParser.ParsedDecls() {
+		if ctx.Err() != nil {
+			return
+		}
+		if td, ok := d.(*schema.TypeDecl); ok {
+			applyTypeDecl(overlays, td)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseErrorDocLegacySingleMethod(t *testing.T) {
+	doc := `Does a thing.
+
+Errors:
+- NotFound: the thing wasn't found
+- PermissionDenied: you can't do that
+`
+	_, errs := parseErrorDoc(doc)
+	want := []*ErrorInput{
+		{Code: "NotFound", Doc: "the thing wasn't found"},
+		{Code: "PermissionDenied", Doc: "you can't do that"},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Fatalf("got %+v, want %+v", errs, want)
+	}
+}
+
+func TestParseErrorDocMethodScoped(t *testing.T) {
+	doc := `Does a thing.
+
+Errors:
+- NotFound: the thing wasn't found
+
+Errors[POST]:
+- AlreadyExists: a thing with that name exists
+`
+	_, errs := parseErrorDoc(doc)
+	want := []*ErrorInput{
+		{Code: "NotFound", Doc: "the thing wasn't found"},
+		{Code: "AlreadyExists", Doc: "a thing with that name exists", Method: "POST"},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Fatalf("got %+v, want %+v", errs, want)
+	}
+}
+
+func TestParseErrorDocOverlappingMethods(t *testing.T) {
+	doc := `Does a thing.
+
+Errors[GET]:
+- NotFound: no such thing
+
+Errors[POST]:
+- AlreadyExists: a thing with that name exists
+- InvalidArgument: bad input
+`
+	remaining, errs := parseErrorDoc(doc)
+	want := []*ErrorInput{
+		{Code: "NotFound", Doc: "no such thing", Method: "GET"},
+		{Code: "AlreadyExists", Doc: "a thing with that name exists", Method: "POST"},
+		{Code: "InvalidArgument", Doc: "bad input", Method: "POST"},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Fatalf("got %+v, want %+v", errs, want)
+	}
+	if remaining != "Does a thing." {
+		t.Fatalf("remaining doc = %q, want %q", remaining, "Does a thing.")
+	}
+}
+
+func TestParsePathDocMethodScoped(t *testing.T) {
+	doc := `Does a thing.
+
+Path:
+- id: the thing's ID
+
+Path[POST]:
+- id: the name of the thing to create
+`
+	_, docs := parsePathDoc(doc)
+	want := map[string]string{
+		"id":      "the thing's ID",
+		"POST id": "the name of the thing to create",
+	}
+	if !reflect.DeepEqual(docs, want) {
+		t.Fatalf("got %+v, want %+v", docs, want)
+	}
+}
+
+func TestMethodPathDocsFallsBackToUnscoped(t *testing.T) {
+	pathDocs := map[string]string{
+		"id":      "the thing's ID",
+		"POST id": "the name of the thing to create",
+	}
+	got := methodPathDocs(pathDocs, []string{"GET", "POST"})
+	want := map[string]map[string]string{
+		"GET":  {"id": "the thing's ID"},
+		"POST": {"id": "the name of the thing to create"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,280 @@
+package ai
+
+import (
+	"context"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+
+	"encr.dev/cli/daemon/apps"
+	"encr.dev/internal/env"
+	"encr.dev/pkg/paths"
+	"encr.dev/v2/internals/parsectx"
+	"encr.dev/v2/internals/perr"
+	"encr.dev/v2/internals/pkginfo"
+	"encr.dev/v2/internals/schema"
+)
+
+const defaultDebounceDelay = 250 * time.Millisecond
+
+// WatcherOptions configures a Watcher.
+type WatcherOptions struct {
+	// DebounceDelay is how long to wait after the last filesystem event
+	// in a burst before reparsing. Defaults to 250ms.
+	DebounceDelay time.Duration
+}
+
+// Watcher incrementally re-parses an app's endpoints as its source
+// files change. Unlike parseCode, it reuses its pkginfo loader and
+// schema.Parser across runs and only re-runs apis.Parser on the
+// packages a given filesystem event actually touched, so unrelated
+// endpoints aren't re-serialized on every edit.
+type Watcher struct {
+	app      *apps.Instance
+	services []ServiceInput
+
+	fsWatcher *fsnotify.Watcher
+	events    chan *SyncResult
+	closeOnce sync.Once
+	done      chan struct{}
+
+	mu           sync.Mutex
+	overlays     *overlays
+	pc           *parsectx.Context
+	loader       *pkginfo.Loader
+	schemaParser *schema.Parser
+	pkgs         map[paths.Pkg]*pkginfo.Package
+	// dirToPkg maps a package's OS directory to its import-path-style
+	// paths.Pkg, the same identity overlays.pkgPaths()/w.pkgs key on.
+	// It's fixed at construction time since the watcher only ever
+	// tracks the packages the watched services already resolved to.
+	dirToPkg map[string]paths.Pkg
+}
+
+// NewWatcher installs a recursive filesystem watcher on app's root and
+// begins delivering re-parsed SyncResults on the returned Watcher's
+// Events channel as matching .go files and overlay paths change.
+func NewWatcher(app *apps.Instance, services []ServiceInput, opts WatcherOptions) (*Watcher, error) {
+	if opts.DebounceDelay <= 0 {
+		opts.DebounceDelay = defaultDebounceDelay
+	}
+
+	ovs, err := newOverlays(app, false, services...)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := token.NewFileSet()
+	errs := perr.NewList(context.Background(), fs, ovs.ReadFile)
+	pc := &parsectx.Context{
+		Ctx: context.Background(),
+		Log: zerolog.Logger{},
+		Build: parsectx.BuildInfo{
+			GOROOT: paths.RootedFSPath(env.EncoreGoRoot(), "."),
+			GOARCH: runtime.GOARCH,
+			GOOS:   runtime.GOOS,
+		},
+		MainModuleDir: paths.RootedFSPath(app.Root(), "."),
+		FS:            fs,
+		ParseTests:    false,
+		Errs:          errs,
+		Overlay:       ovs,
+	}
+
+	loader := pkginfo.New(pc)
+	schemaParser := schema.NewParser(pc, loader)
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := addRecursive(fsWatcher, app.Root().ToIO()); err != nil {
+		fsWatcher.Close()
+		return nil, err
+	}
+
+	w := &Watcher{
+		app:          app,
+		services:     services,
+		fsWatcher:    fsWatcher,
+		events:       make(chan *SyncResult, 1),
+		done:         make(chan struct{}),
+		overlays:     ovs,
+		pc:           pc,
+		loader:       loader,
+		schemaParser: schemaParser,
+		pkgs:         map[paths.Pkg]*pkginfo.Package{},
+		dirToPkg:     map[string]paths.Pkg{},
+	}
+
+	for _, pkg := range ovs.pkgPaths() {
+		info, _ := loader.LoadPkg(token.NoPos, pkg)
+		w.pkgs[pkg] = info
+		if info != nil {
+			w.dirToPkg[info.FSPath.ToIO()] = pkg
+		}
+	}
+
+	go w.run(opts.DebounceDelay)
+	return w, nil
+}
+
+// addRecursive walks root and adds every directory (skipping dotfiles
+// and node_modules) to fsWatcher, since fsnotify.Watcher.Add only
+// watches the exact directory it's given and Encore app packages are
+// nested arbitrarily deep under the app root.
+func addRecursive(fsWatcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if name := d.Name(); name != "." && (strings.HasPrefix(name, ".") || name == "node_modules") {
+			return filepath.SkipDir
+		}
+		return fsWatcher.Add(path)
+	})
+}
+
+// Events returns the channel of incrementally re-parsed sync results.
+// A result is delivered once per debounced batch of relevant
+// filesystem changes; only the packages touched by that batch are
+// re-parsed.
+func (w *Watcher) Events() <-chan *SyncResult {
+	return w.events
+}
+
+// Close stops the watcher and releases its filesystem handle.
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() { close(w.done) })
+	return w.fsWatcher.Close()
+}
+
+func (w *Watcher) run(debounceDelay time.Duration) {
+	var debounce *time.Timer
+	var dirtyMu sync.Mutex
+	dirty := map[paths.Pkg]bool{}
+
+	// flush runs on the timer goroutine time.AfterFunc spawns, which
+	// executes concurrently with the select loop below, so dirty needs
+	// its own lock rather than being touched from both goroutines bare.
+	flush := func() {
+		dirtyMu.Lock()
+		if len(dirty) == 0 {
+			dirtyMu.Unlock()
+			return
+		}
+		affected := make([]paths.Pkg, 0, len(dirty))
+		for pkg := range dirty {
+			affected = append(affected, pkg)
+			delete(dirty, pkg)
+		}
+		dirtyMu.Unlock()
+		w.deliver(w.reparse(affected))
+	}
+
+	for {
+		select {
+		case <-w.done:
+			if debounce != nil {
+				debounce.Stop()
+			}
+			return
+		case ev, ok := <-w.fsWatcher.Events:
+			if !ok {
+				return
+			}
+			if !w.relevant(ev.Name) {
+				continue
+			}
+			pkg, ok := w.pkgForFile(ev.Name)
+			if !ok {
+				continue
+			}
+			w.mu.Lock()
+			delete(w.pkgs, pkg)
+			w.mu.Unlock()
+			dirtyMu.Lock()
+			dirty[pkg] = true
+			dirtyMu.Unlock()
+			if debounce == nil {
+				debounce = time.AfterFunc(debounceDelay, flush)
+			} else {
+				debounce.Reset(debounceDelay)
+			}
+		case _, ok := <-w.fsWatcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+func (w *Watcher) deliver(res *SyncResult) {
+	select {
+	case w.events <- res:
+	default:
+		select {
+		case <-w.events:
+		default:
+		}
+		w.events <- res
+	}
+}
+
+func (w *Watcher) relevant(name string) bool {
+	if w.overlays.isOverlayPath(name) {
+		return true
+	}
+	return filepath.Ext(name) == ".go"
+}
+
+// pkgForFile resolves the package a changed file belongs to, using the
+// same OS-directory-to-paths.Pkg identity the watched packages were
+// registered under in NewWatcher. Files outside those packages (e.g. a
+// new, not-yet-resolved service directory) report ok=false.
+func (w *Watcher) pkgForFile(name string) (paths.Pkg, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	pkg, ok := w.dirToPkg[filepath.Dir(name)]
+	return pkg, ok
+}
+
+// reparse re-runs the resource and schema parsers over exactly the
+// affected packages, reusing w.schemaParser and any still-cached
+// pkginfo.Packages, then folds the results into the overlay endpoints.
+func (w *Watcher) reparse(affected []paths.Pkg) (rtn *SyncResult) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	defer func() {
+		perr.CatchBailout(recover())
+		if rtn == nil {
+			rtn = &SyncResult{Services: w.services}
+		}
+	}()
+
+	for _, pkgPath := range affected {
+		pkg, ok := w.pkgs[pkgPath]
+		if !ok {
+			pkg, _ = w.loader.LoadPkg(token.NoPos, pkgPath)
+			w.pkgs[pkgPath] = pkg
+		}
+		if pkg == nil {
+			continue
+		}
+
+		parsePackage(w.pc.Ctx, w.overlays, w.schemaParser, w.pc, pkg)
+	}
+
+	return &SyncResult{Services: w.services}
+}
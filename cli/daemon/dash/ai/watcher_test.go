@@ -0,0 +1,51 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestAddRecursiveWatchesNestedDirectories(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "svc", "sub")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	skipped := filepath.Join(root, "node_modules", "dep")
+	if err := os.MkdirAll(skipped, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	dotDir := filepath.Join(root, ".git")
+	if err := os.MkdirAll(dotDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatalf("fsnotify.NewWatcher: %v", err)
+	}
+	defer fsWatcher.Close()
+
+	if err := addRecursive(fsWatcher, root); err != nil {
+		t.Fatalf("addRecursive: %v", err)
+	}
+
+	watched := map[string]bool{}
+	for _, dir := range fsWatcher.WatchList() {
+		watched[dir] = true
+	}
+
+	for _, want := range []string{root, filepath.Join(root, "svc"), nested} {
+		if !watched[want] {
+			t.Errorf("expected %q to be watched, got %v", want, fsWatcher.WatchList())
+		}
+	}
+	for _, notWant := range []string{filepath.Join(root, "node_modules"), skipped, dotDir} {
+		if watched[notWant] {
+			t.Errorf("expected %q to be skipped, got %v", notWant, fsWatcher.WatchList())
+		}
+	}
+}